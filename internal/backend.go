@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/kgaughan/gcredstash/pkg/metrics"
+)
+
+// Material is the encrypted record a StorageBackend stores for a single
+// {name, version} pair. Key is the KMS-wrapped data key, Contents is the
+// encrypted secret, and HMAC authenticates Contents; all three are stored
+// in the same base64/hex encodings PutItem receives them in.
+type Material struct {
+	Key      string
+	Contents string
+	HMAC     string
+}
+
+// StorageBackend is the metadata/ciphertext store behind a Driver. The
+// DynamoDB-backed implementation (DynamoBackend) is the default; S3Backend
+// lets gcredstash run in accounts/regions without a provisioned DynamoDB
+// table.
+type StorageBackend interface {
+	GetMaterialWithoutVersion(ctx context.Context, name, table string) (*Material, error)
+	GetMaterialWithVersion(ctx context.Context, name, version, table string) (*Material, error)
+	GetHighestVersion(ctx context.Context, name, table string) (int, error)
+	PutItem(ctx context.Context, name, version string, key, contents, hmac []byte, table string) error
+	DeleteItem(ctx context.Context, name, version, table string) error
+	// DeleteSecrets deletes every version of name, or just version when
+	// version is non-empty, printing what it deleted to os.Stderr the way
+	// the CLI has always reported deletions.
+	DeleteSecrets(ctx context.Context, name, version, table string) error
+	// ListSecrets returns name -> highest version for every secret in table.
+	ListSecrets(ctx context.Context, table string) (map[string]string, error)
+	// RewrapItem atomically writes a new {name, newVersion} item carrying
+	// key, contents and hmac, conditional on the {name, version} item it's
+	// replacing still matching old. This guards Driver.RewrapSecret against
+	// racing a concurrent rotate or delete of the version it decrypted.
+	// DynamoBackend does this with a single TransactWriteItems call;
+	// S3Backend, lacking cross-object transactions, does its best with a
+	// read-verify-write sequence.
+	RewrapItem(ctx context.Context, name, version, newVersion string, old *Material, key, contents, hmac []byte, table string) error
+}
+
+// newBackend picks a StorageBackend based on GCREDSTASH_BACKEND, defaulting
+// to DynamoDB. GCREDSTASH_BACKEND=s3 requires GCREDSTASH_S3_BUCKET to be set;
+// GCREDSTASH_S3_PREFIX is optional and defaults to no prefix.
+func newBackend(ctx context.Context, cfg aws.Config, registry *metrics.Registry, daxEndpoint string) (StorageBackend, error) {
+	switch backend := os.Getenv("GCREDSTASH_BACKEND"); backend {
+	case "", "dynamo":
+		return newDynamoBackend(ctx, cfg, registry, daxEndpoint)
+	case "s3":
+		return newS3Backend(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBackend, backend)
+	}
+}
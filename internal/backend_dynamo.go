@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	daxv2 "github.com/aws/aws-dax-go-v2/dax"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/kgaughan/gcredstash/pkg/metrics"
+)
+
+// DynamoDBAPI is the subset of *dynamodb.Client (or a DAX client with the
+// same shape) that DynamoBackend needs.
+type DynamoDBAPI interface {
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// DynamoBackend is the original StorageBackend, storing one DynamoDB item
+// per {name, version}.
+type DynamoBackend struct {
+	Ddb DynamoDBAPI
+
+	// ConsistentReads controls whether GetMaterialWith[out]Version issue
+	// strongly consistent reads. It's forced on for plain DynamoDB access,
+	// but turned off when Ddb is DAX-backed so ordinary GetSecret calls
+	// benefit from microsecond-latency cached lookups. GetHighestVersion
+	// always reads consistently regardless of this setting, since it needs
+	// to bypass the cache to avoid racing PutSecret.
+	ConsistentReads bool
+
+	// Metrics records gcredstash_dax_reads_total when ConsistentReads is
+	// false, i.e. when Ddb is DAX-backed and an eventually-consistent read
+	// succeeds. The DAX client doesn't expose whether a read actually hit
+	// its cache, so this counts every such read rather than true cache
+	// hits. It's never nil; newDynamoBackend/newDaxBackend always set it.
+	Metrics *metrics.Registry
+}
+
+// newDynamoBackend picks a plain DynamoDB or DAX-backed client. daxEndpoint,
+// when non-empty, overrides GCREDSTASH_DAX_ENDPOINT -- the same
+// flag-over-env precedence ServeCommand uses for -listen/GCREDSTASH_LISTEN.
+func newDynamoBackend(ctx context.Context, cfg aws.Config, registry *metrics.Registry, daxEndpoint string) (StorageBackend, error) {
+	if daxEndpoint == "" {
+		daxEndpoint = os.Getenv("GCREDSTASH_DAX_ENDPOINT")
+	}
+
+	if daxEndpoint != "" {
+		return newDaxBackend(ctx, cfg, daxEndpoint, registry)
+	}
+
+	return &DynamoBackend{
+		Ddb:             metrics.NewDynamoDB(dynamodb.NewFromConfig(cfg), registry),
+		ConsistentReads: true,
+		Metrics:         registry,
+	}, nil
+}
+
+func newDaxBackend(ctx context.Context, cfg aws.Config, daxEndpoint string, registry *metrics.Registry) (StorageBackend, error) {
+	daxClient, err := daxv2.NewFromConfig(cfg, daxEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create DAX client for %s: %w", daxEndpoint, err)
+	}
+
+	return &DynamoBackend{
+		Ddb:             metrics.NewDynamoDB(daxClient, registry),
+		ConsistentReads: false,
+		Metrics:         registry,
+	}, nil
+}
+
+func (backend *DynamoBackend) GetMaterialWithoutVersion(ctx context.Context, name, table string) (*Material, error) {
+	params := &dynamodb.QueryInput{
+		TableName:                aws.String(table),
+		Limit:                    aws.Int32(1),
+		ConsistentRead:           aws.Bool(backend.ConsistentReads),
+		ScanIndexForward:         aws.Bool(false),
+		KeyConditionExpression:   aws.String("#name = :name"),
+		ExpressionAttributeNames: map[string]string{"#name": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":name": &types.AttributeValueMemberS{Value: name},
+		},
+	}
+
+	resp, err := backend.Ddb.Query(ctx, params)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if resp.Count == 0 {
+		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	}
+
+	backend.recordDaxRead()
+
+	return itemToMaterial(resp.Items[0]), nil
+}
+
+func (backend *DynamoBackend) GetMaterialWithVersion(ctx context.Context, name, version, table string) (*Material, error) {
+	params := &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		ConsistentRead: aws.Bool(backend.ConsistentReads),
+		Key: map[string]types.AttributeValue{
+			"name":    &types.AttributeValueMemberS{Value: name},
+			"version": &types.AttributeValueMemberS{Value: version},
+		},
+	}
+
+	resp, err := backend.Ddb.GetItem(ctx, params)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if resp.Item == nil {
+		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	}
+
+	backend.recordDaxRead()
+
+	return itemToMaterial(resp.Item), nil
+}
+
+// recordDaxRead counts a successful read issued through a DAX-backed client
+// when this backend isn't forcing strongly consistent reads. It can't tell
+// a real cache hit from a cache miss -- the DAX client gives no such
+// signal -- so it counts every eventually-consistent read that succeeds.
+func (backend *DynamoBackend) recordDaxRead() {
+	if !backend.ConsistentReads {
+		backend.Metrics.DaxReadsTotal.Inc()
+	}
+}
+
+func attributeString(item map[string]types.AttributeValue, key string) string {
+	if s, ok := item[key].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+
+	if b, ok := item[key].(*types.AttributeValueMemberB); ok {
+		return string(b.Value)
+	}
+
+	return ""
+}
+
+func itemToMaterial(item map[string]types.AttributeValue) *Material {
+	return &Material{
+		Key:      attributeString(item, "key"),
+		Contents: attributeString(item, "contents"),
+		HMAC:     attributeString(item, "hmac"),
+	}
+}
+
+func (backend *DynamoBackend) GetHighestVersion(ctx context.Context, name, table string) (int, error) {
+	params := &dynamodb.QueryInput{
+		TableName:                aws.String(table),
+		Limit:                    aws.Int32(1),
+		ConsistentRead:           aws.Bool(true),
+		ScanIndexForward:         aws.Bool(false),
+		KeyConditionExpression:   aws.String("#name = :name"),
+		ExpressionAttributeNames: map[string]string{"#name": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":name": &types.AttributeValueMemberS{Value: name},
+		},
+		ProjectionExpression: aws.String("version"),
+	}
+
+	resp, err := backend.Ddb.Query(ctx, params)
+	if err != nil {
+		return -1, fmt.Errorf("can't query version: %w", err)
+	}
+
+	if resp.Count == 0 {
+		return 0, nil
+	}
+
+	version := attributeString(resp.Items[0], "version")
+	versionNum := Atoi(version)
+
+	return versionNum, nil
+}
+
+func (backend *DynamoBackend) PutItem(ctx context.Context, name, version string, key, contents, hmac []byte, table string) error {
+	b64key := B64Encode(key)
+	b64contents := B64Encode(contents)
+	hexHmac := HexEncode(hmac)
+
+	params := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item: map[string]types.AttributeValue{
+			"name":     &types.AttributeValueMemberS{Value: name},
+			"version":  &types.AttributeValueMemberS{Value: version},
+			"key":      &types.AttributeValueMemberS{Value: b64key},
+			"contents": &types.AttributeValueMemberS{Value: b64contents},
+			"hmac":     &types.AttributeValueMemberS{Value: hexHmac},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#name)"),
+		ExpressionAttributeNames: map[string]string{"#name": "name"},
+	}
+
+	_, err := backend.Ddb.PutItem(ctx, params)
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: %s", ErrItemExists, err)
+		}
+		return fmt.Errorf("can't store secret: %w", err)
+	}
+
+	return nil
+}
+
+func (backend *DynamoBackend) getDeleteTargetWithoutVersion(ctx context.Context, name, table string) (map[string]string, error) {
+	params := &dynamodb.QueryInput{
+		TableName:                aws.String(table),
+		ConsistentRead:           aws.Bool(true),
+		KeyConditionExpression:   aws.String("#name = :name"),
+		ExpressionAttributeNames: map[string]string{"#name": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":name": &types.AttributeValueMemberS{Value: name},
+		},
+	}
+
+	resp, err := backend.Ddb.Query(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("can't find deletion target: %w", err)
+	}
+
+	if resp.Count == 0 {
+		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	}
+
+	items := map[string]string{}
+	for _, i := range resp.Items {
+		items[attributeString(i, "name")] = attributeString(i, "version")
+	}
+
+	return items, nil
+}
+
+func (backend *DynamoBackend) getDeleteTargetWithVersion(ctx context.Context, name, version, table string) (map[string]string, error) {
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]types.AttributeValue{
+			"name":    &types.AttributeValueMemberS{Value: name},
+			"version": &types.AttributeValueMemberS{Value: version},
+		},
+	}
+
+	resp, err := backend.Ddb.GetItem(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("can't find deletion target: %w", err)
+	}
+
+	if resp.Item == nil {
+		versionNum := Atoi(version)
+		return nil, fmt.Errorf(`%w: {"name": %q, "version": %d}`, ErrItemNotFound, name, versionNum)
+	}
+
+	return map[string]string{
+		attributeString(resp.Item, "name"): attributeString(resp.Item, "version"),
+	}, nil
+}
+
+func (backend *DynamoBackend) DeleteItem(ctx context.Context, name, version, table string) error {
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key: map[string]types.AttributeValue{
+			"name":    &types.AttributeValueMemberS{Value: name},
+			"version": &types.AttributeValueMemberS{Value: version},
+		},
+	}
+
+	if _, err := backend.Ddb.DeleteItem(ctx, params); err != nil {
+		return fmt.Errorf("can't delete secret %q (%v): %w", name, version, err)
+	}
+
+	return nil
+}
+
+func (backend *DynamoBackend) DeleteSecrets(ctx context.Context, name, version, table string) error {
+	var items map[string]string
+	var err error
+
+	if version == "" {
+		items, err = backend.getDeleteTargetWithoutVersion(ctx, name, table)
+	} else {
+		items, err = backend.getDeleteTargetWithVersion(ctx, name, version, table)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for name, version := range items {
+		err := backend.DeleteItem(ctx, name, version, table)
+		if err != nil {
+			return err
+		}
+
+		versionNum := Atoi(version)
+		fmt.Fprintf(os.Stderr, "Deleting %s -- version %d\n", name, versionNum)
+	}
+
+	return nil
+}
+
+// RewrapItem writes {name, newVersion} and checks {name, version} still
+// carries old.Contents in a single TransactWriteItems call, so the two
+// happen atomically: either both succeed, or neither does.
+func (backend *DynamoBackend) RewrapItem(ctx context.Context, name, version, newVersion string, old *Material, key, contents, hmac []byte, table string) error {
+	b64key := B64Encode(key)
+	b64contents := B64Encode(contents)
+	hexHmac := HexEncode(hmac)
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName: aws.String(table),
+					Item: map[string]types.AttributeValue{
+						"name":     &types.AttributeValueMemberS{Value: name},
+						"version":  &types.AttributeValueMemberS{Value: newVersion},
+						"key":      &types.AttributeValueMemberS{Value: b64key},
+						"contents": &types.AttributeValueMemberS{Value: b64contents},
+						"hmac":     &types.AttributeValueMemberS{Value: hexHmac},
+					},
+					ConditionExpression:      aws.String("attribute_not_exists(#name)"),
+					ExpressionAttributeNames: map[string]string{"#name": "name"},
+				},
+			},
+			{
+				ConditionCheck: &types.ConditionCheck{
+					TableName: aws.String(table),
+					Key: map[string]types.AttributeValue{
+						"name":    &types.AttributeValueMemberS{Value: name},
+						"version": &types.AttributeValueMemberS{Value: version},
+					},
+					ConditionExpression: aws.String("contents = :contents"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":contents": &types.AttributeValueMemberS{Value: old.Contents},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := backend.Ddb.TransactWriteItems(ctx, input); err != nil {
+		var condErr *types.TransactionCanceledException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("%w: %s was modified or deleted concurrently: %s", ErrItemExists, name, err)
+		}
+		return fmt.Errorf("can't rewrap secret: %w", err)
+	}
+
+	return nil
+}
+
+func (backend *DynamoBackend) ListSecrets(ctx context.Context, table string) (map[string]string, error) {
+	params := &dynamodb.ScanInput{
+		TableName:                aws.String(table),
+		ProjectionExpression:     aws.String("#name,version"),
+		ExpressionAttributeNames: map[string]string{"#name": "name"},
+	}
+
+	resp, err := backend.Ddb.Scan(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("can't list secrets: %w", err)
+	}
+
+	items := map[string]string{}
+
+	for _, i := range resp.Items {
+		name := attributeString(i, "name")
+		version := attributeString(i, "version")
+
+		if current, ok := items[name]; ok && Atoi(current) >= Atoi(version) {
+			continue
+		}
+
+		items[name] = version
+	}
+
+	return items, nil
+}
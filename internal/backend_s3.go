@@ -0,0 +1,351 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3API is the subset of *s3.Client S3Backend needs.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3Backend stores each {name, version} as a JSON object under
+// Prefix/name/version, plus a small JSON manifest object at
+// Prefix/name/manifest.json listing the versions known for that name. It
+// lets gcredstash run in accounts/regions without a provisioned DynamoDB
+// table; "table" arguments are accepted for interface compatibility with
+// DynamoBackend but otherwise unused.
+type S3Backend struct {
+	S3     S3API
+	Bucket string
+	Prefix string
+}
+
+func newS3Backend(cfg aws.Config) (StorageBackend, error) {
+	bucket := os.Getenv("GCREDSTASH_S3_BUCKET")
+	if bucket == "" {
+		return nil, fmt.Errorf("%w: GCREDSTASH_S3_BUCKET is required", ErrUnknownBackend)
+	}
+
+	return &S3Backend{
+		S3:     s3.NewFromConfig(cfg),
+		Bucket: bucket,
+		Prefix: os.Getenv("GCREDSTASH_S3_PREFIX"),
+	}, nil
+}
+
+type s3Manifest struct {
+	Versions []string `json:"versions"`
+}
+
+type s3Material struct {
+	Key      string `json:"key"`
+	Contents string `json:"contents"`
+	HMAC     string `json:"hmac"`
+}
+
+func (backend *S3Backend) manifestKey(name string) string {
+	return path.Join(backend.Prefix, name, "manifest.json")
+}
+
+func (backend *S3Backend) itemKey(name, version string) string {
+	return path.Join(backend.Prefix, name, version)
+}
+
+// nameFromManifestKey reverses manifestKey, recovering name (which may
+// itself contain slashes, e.g. "app/db") from the key of one of the
+// manifest.json objects ListSecrets finds.
+func (backend *S3Backend) nameFromManifestKey(key string) string {
+	name := strings.TrimSuffix(key, "/manifest.json")
+
+	if backend.Prefix != "" {
+		name = strings.TrimPrefix(name, path.Clean(backend.Prefix)+"/")
+	}
+
+	return name
+}
+
+func (backend *S3Backend) getManifest(ctx context.Context, name string) (*s3Manifest, error) {
+	resp, err := backend.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(backend.Bucket),
+		Key:    aws.String(backend.manifestKey(name)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return &s3Manifest{}, nil
+		}
+		return nil, fmt.Errorf("can't read manifest for %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	var manifest s3Manifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("can't parse manifest for %q: %w", name, err)
+	}
+
+	return &manifest, nil
+}
+
+func (backend *S3Backend) putManifest(ctx context.Context, name string, manifest *s3Manifest) error {
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("can't encode manifest for %q: %w", name, err)
+	}
+
+	_, err = backend.S3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(backend.Bucket),
+		Key:    aws.String(backend.manifestKey(name)),
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return fmt.Errorf("can't write manifest for %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func isS3NotFound(err error) bool {
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+
+	var notFound *s3types.NotFound
+	return errors.As(err, &notFound)
+}
+
+func (backend *S3Backend) highestVersion(manifest *s3Manifest) string {
+	versions := append([]string(nil), manifest.Versions...)
+	sort.Slice(versions, func(i, j int) bool {
+		return Atoi(versions[i]) < Atoi(versions[j])
+	})
+
+	if len(versions) == 0 {
+		return ""
+	}
+
+	return versions[len(versions)-1]
+}
+
+func (backend *S3Backend) GetHighestVersion(ctx context.Context, name, table string) (int, error) {
+	manifest, err := backend.getManifest(ctx, name)
+	if err != nil {
+		return -1, err
+	}
+
+	version := backend.highestVersion(manifest)
+	if version == "" {
+		return 0, nil
+	}
+
+	return Atoi(version), nil
+}
+
+func (backend *S3Backend) GetMaterialWithoutVersion(ctx context.Context, name, table string) (*Material, error) {
+	manifest, err := backend.getManifest(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	version := backend.highestVersion(manifest)
+	if version == "" {
+		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	}
+
+	//nolint:wrapcheck
+	return backend.GetMaterialWithVersion(ctx, name, version, table)
+}
+
+func (backend *S3Backend) GetMaterialWithVersion(ctx context.Context, name, version, table string) (*Material, error) {
+	resp, err := backend.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(backend.Bucket),
+		Key:    aws.String(backend.itemKey(name, version)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+		}
+		return nil, fmt.Errorf("can't fetch %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %q: %w", name, err)
+	}
+
+	var material s3Material
+	if err := json.Unmarshal(body, &material); err != nil {
+		return nil, fmt.Errorf("can't parse %q: %w", name, err)
+	}
+
+	return &Material{Key: material.Key, Contents: material.Contents, HMAC: material.HMAC}, nil
+}
+
+func (backend *S3Backend) PutItem(ctx context.Context, name, version string, key, contents, hmac []byte, table string) error {
+	manifest, err := backend.getManifest(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range manifest.Versions {
+		if v == version {
+			return fmt.Errorf("%w: {\"name\": %q, \"version\": %q}", ErrItemExists, name, version)
+		}
+	}
+
+	material := s3Material{
+		Key:      B64Encode(key),
+		Contents: B64Encode(contents),
+		HMAC:     HexEncode(hmac),
+	}
+
+	body, err := json.Marshal(material)
+	if err != nil {
+		return fmt.Errorf("can't encode %q: %w", name, err)
+	}
+
+	_, err = backend.S3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(backend.Bucket),
+		Key:    aws.String(backend.itemKey(name, version)),
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return fmt.Errorf("can't store %q: %w", name, err)
+	}
+
+	manifest.Versions = append(manifest.Versions, version)
+
+	return backend.putManifest(ctx, name, manifest)
+}
+
+// RewrapItem has no S3 equivalent to DynamoDB's TransactWriteItems, so it
+// does its best with a read-verify-write sequence: re-fetch {name, version}
+// and check it still carries old.Contents before writing newVersion. This
+// narrows, but doesn't close, the race window a true transaction would.
+func (backend *S3Backend) RewrapItem(ctx context.Context, name, version, newVersion string, old *Material, key, contents, hmac []byte, table string) error {
+	current, err := backend.GetMaterialWithVersion(ctx, name, version, table)
+	if err != nil {
+		return err
+	}
+
+	if current.Contents != old.Contents {
+		return fmt.Errorf("%w: {\"name\": %q, \"version\": %q} was modified or deleted concurrently", ErrItemExists, name, version)
+	}
+
+	//nolint:wrapcheck
+	return backend.PutItem(ctx, name, newVersion, key, contents, hmac, table)
+}
+
+func (backend *S3Backend) DeleteItem(ctx context.Context, name, version, table string) error {
+	_, err := backend.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(backend.Bucket),
+		Key:    aws.String(backend.itemKey(name, version)),
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete secret %q (%v): %w", name, version, err)
+	}
+
+	return nil
+}
+
+func (backend *S3Backend) DeleteSecrets(ctx context.Context, name, version, table string) error {
+	manifest, err := backend.getManifest(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if len(manifest.Versions) == 0 {
+		return fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	}
+
+	targets := manifest.Versions
+	if version != "" {
+		found := false
+
+		for _, v := range manifest.Versions {
+			if v == version {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf(`%w: {"name": %q, "version": %q}`, ErrItemNotFound, name, version)
+		}
+
+		targets = []string{version}
+	}
+
+	remaining := map[string]bool{}
+	for _, v := range manifest.Versions {
+		remaining[v] = true
+	}
+
+	for _, v := range targets {
+		if err := backend.DeleteItem(ctx, name, v, table); err != nil {
+			return err
+		}
+
+		delete(remaining, v)
+		fmt.Fprintf(os.Stderr, "Deleting %s -- version %d\n", name, Atoi(v))
+	}
+
+	newManifest := &s3Manifest{}
+	for v := range remaining {
+		newManifest.Versions = append(newManifest.Versions, v)
+	}
+
+	return backend.putManifest(ctx, name, newManifest)
+}
+
+func (backend *S3Backend) ListSecrets(ctx context.Context, table string) (map[string]string, error) {
+	items := map[string]string{}
+
+	paginator := s3.NewListObjectsV2Paginator(backend.S3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(backend.Bucket),
+		Prefix: aws.String(backend.Prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("can't list secrets: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			if path.Base(*obj.Key) != "manifest.json" {
+				continue
+			}
+
+			items[backend.nameFromManifestKey(*obj.Key)] = ""
+		}
+	}
+
+	for name := range items {
+		version, err := backend.GetHighestVersion(ctx, name, table)
+		if err != nil {
+			return nil, err
+		}
+
+		items[name] = fmt.Sprintf("%d", version)
+	}
+
+	return items, nil
+}
@@ -1,17 +1,16 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
-	"github.com/aws/aws-sdk-go/service/kms"
-	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"github.com/kgaughan/gcredstash/pkg/metrics"
 )
 
 var (
@@ -20,79 +19,63 @@ var (
 	ErrCredNotMatched = errors.New("could not decrypt HMAC key with KMS: the encryption context provided may not match the one used when the credential was stored")
 	ErrBadHMAC        = errors.New("computed HMAC does not match stored HMAC")
 	ErrVersionExists  = errors.New("version already in the credential store - use the -v flag to specify a new version")
+	ErrItemExists     = errors.New("item already exists")
+	ErrUnknownBackend = errors.New("unknown GCREDSTASH_BACKEND")
 )
 
-type Driver struct {
-	Ddb dynamodbiface.DynamoDBAPI
-	Kms kmsiface.KMSAPI
+// maxAutoVersionAttempts bounds how many attempts PutSecretAutoVersion makes
+// in total (the first try plus retries after losing a race with another
+// writer incrementing the same name).
+const maxAutoVersionAttempts = 10
+
+// KMSAPI is the subset of *kms.Client the Driver needs, narrowed to an
+// interface so tests (and the metrics.InstrumentedKMS wrapper NewDriver
+// installs) can substitute their own implementation.
+type KMSAPI interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
 }
 
-func NewDriver() (*Driver, error) {
-	awsSession, err := session.NewSession()
-	if err != nil {
-		return nil, fmt.Errorf("cannot create session: %w", err)
-	}
-	driver := &Driver{
-		Ddb: dynamodb.New(awsSession),
-		Kms: kms.New(awsSession),
-	}
-	return driver, nil
-}
+type Driver struct {
+	Backend StorageBackend
+	Kms     KMSAPI
 
-func (driver *Driver) GetMaterialWithoutVersion(name, table string) (map[string]*dynamodb.AttributeValue, error) {
-	params := &dynamodb.QueryInput{
-		TableName:                aws.String(table),
-		Limit:                    aws.Int64(1),
-		ConsistentRead:           aws.Bool(true),
-		ScanIndexForward:         aws.Bool(false),
-		KeyConditionExpression:   aws.String("#name = :name"),
-		ExpressionAttributeNames: map[string]*string{"#name": aws.String("name")},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":name": {S: aws.String(name)},
-		},
-	}
+	// Metrics holds the Prometheus collectors recording this Driver's
+	// DynamoDB and KMS traffic; ServeCommand exposes it over HTTP.
+	Metrics *metrics.Registry
+}
 
-	resp, err := driver.Ddb.Query(params)
+// NewDriver builds a Driver from the ambient AWS config. daxEndpoint, when
+// non-empty, takes precedence over GCREDSTASH_DAX_ENDPOINT and should be
+// sourced from a -dax-endpoint flag the same way ServeCommand pairs -listen
+// with GCREDSTASH_LISTEN; pass "" to fall back to the environment alone.
+func NewDriver(ctx context.Context, daxEndpoint string) (*Driver, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
-		//nolint:wrapcheck
-		return nil, err
-	}
-
-	if *resp.Count == 0 {
-		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+		return nil, fmt.Errorf("cannot load AWS config: %w", err)
 	}
 
-	return resp.Items[0], nil
-}
-
-func (driver *Driver) GetMaterialWithVersion(name, version, table string) (map[string]*dynamodb.AttributeValue, error) {
-	params := &dynamodb.GetItemInput{
-		TableName: aws.String(table),
-		Key: map[string]*dynamodb.AttributeValue{
-			"name":    {S: aws.String(name)},
-			"version": {S: aws.String(version)},
-		},
-	}
+	registry := metrics.NewRegistry()
 
-	resp, err := driver.Ddb.GetItem(params)
+	backend, err := newBackend(ctx, cfg, registry, daxEndpoint)
 	if err != nil {
-		//nolint:wrapcheck
 		return nil, err
 	}
 
-	if resp.Item == nil {
-		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
+	driver := &Driver{
+		Backend: backend,
+		Kms:     metrics.NewKMS(kms.NewFromConfig(cfg), registry),
+		Metrics: registry,
 	}
-
-	return resp.Item, nil
+	return driver, nil
 }
 
-func (driver *Driver) DecryptMaterial(name string, material map[string]*dynamodb.AttributeValue, context map[string]string) (string, error) {
-	data := B64Decode(*material["key"].S)
-	dataKey, hmacKey, err := KmsDecrypt(driver.Kms, data, context)
+func (driver *Driver) DecryptMaterial(ctx context.Context, name string, material *Material, ctxMap map[string]string) (string, error) {
+	data := B64Decode(material.Key)
+	dataKey, hmacKey, err := KmsDecrypt(ctx, driver.Kms, data, ctxMap)
 	if err != nil {
 		if strings.Contains(err.Error(), "InvalidCiphertextException") {
-			if len(context) < 1 {
+			if len(ctxMap) < 1 {
 				return "", fmt.Errorf("%s: %w", name, ErrNeedContext)
 			}
 			return "", fmt.Errorf("%s: %w", name, ErrCredNotMatched)
@@ -100,14 +83,8 @@ func (driver *Driver) DecryptMaterial(name string, material map[string]*dynamodb
 		return "", err
 	}
 
-	var hmac []byte
-	if len(material["hmac"].B) == 0 {
-		hmac = HexDecode(*material["hmac"].S)
-	} else {
-		hmac = HexDecode(string(material["hmac"].B))
-	}
-
-	contents := B64Decode(*material["contents"].S)
+	hmac := HexDecode(material.HMAC)
+	contents := B64Decode(material.Contents)
 	if !ValidateHMAC(contents, hmac, hmacKey) {
 		return "", fmt.Errorf("%s: %w", name, ErrBadHMAC)
 	}
@@ -117,202 +94,74 @@ func (driver *Driver) DecryptMaterial(name string, material map[string]*dynamodb
 	return string(decrypted), nil
 }
 
-func (driver *Driver) GetHighestVersion(name, table string) (int, error) {
-	params := &dynamodb.QueryInput{
-		TableName:                aws.String(table),
-		Limit:                    aws.Int64(1),
-		ConsistentRead:           aws.Bool(true),
-		ScanIndexForward:         aws.Bool(false),
-		KeyConditionExpression:   aws.String("#name = :name"),
-		ExpressionAttributeNames: map[string]*string{"#name": aws.String("name")},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":name": {S: aws.String(name)},
-		},
-		ProjectionExpression: aws.String("version"),
-	}
-
-	resp, err := driver.Ddb.Query(params)
+func (driver *Driver) PutSecret(ctx context.Context, name, secret, version, kmsKey, table string, ctxMap map[string]string) error {
+	dataKey, hmacKey, wrappedKey, err := KmsGenerateDataKey(ctx, driver.Kms, kmsKey, ctxMap)
 	if err != nil {
-		return -1, fmt.Errorf("can't query version: %w", err)
-	}
-
-	if *resp.Count == 0 {
-		return 0, nil
-	}
-
-	version := *resp.Items[0]["version"].S
-	versionNum := Atoi(version)
-
-	return versionNum, nil
-}
-
-func (driver *Driver) PutItem(name, version string, key, contents, hmac []byte, table string) error {
-	b64key := B64Encode(key)
-	b64contents := B64Encode(contents)
-	hexHmac := HexEncode(hmac)
-
-	params := &dynamodb.PutItemInput{
-		TableName: aws.String(table),
-		Item: map[string]*dynamodb.AttributeValue{
-			"name":     {S: aws.String(name)},
-			"version":  {S: aws.String(version)},
-			"key":      {S: aws.String(b64key)},
-			"contents": {S: aws.String(b64contents)},
-			"hmac":     {S: aws.String(hexHmac)},
-		},
-		ConditionExpression:      aws.String("attribute_not_exists(#name)"),
-		ExpressionAttributeNames: map[string]*string{"#name": aws.String("name")},
-	}
-
-	_, err := driver.Ddb.PutItem(params)
-	if err != nil {
-		return fmt.Errorf("can't store secret: %w", err)
-	}
-
-	return nil
-}
-
-func (driver *Driver) GetDeleteTargetWithoutVersion(name, table string) (map[*string]*string, error) {
-	items := map[*string]*string{}
-
-	params := &dynamodb.QueryInput{
-		TableName:                aws.String(table),
-		ConsistentRead:           aws.Bool(true),
-		KeyConditionExpression:   aws.String("#name = :name"),
-		ExpressionAttributeNames: map[string]*string{"#name": aws.String("name")},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":name": {S: aws.String(name)},
-		},
-	}
-
-	resp, err := driver.Ddb.Query(params)
-	if err != nil {
-		return nil, fmt.Errorf("can't find deletion target: %w", err)
-	}
-
-	if *resp.Count == 0 {
-		return nil, fmt.Errorf(`%w: {"name": %q}`, ErrItemNotFound, name)
-	}
-
-	for _, i := range resp.Items {
-		items[i["name"].S] = i["version"].S
+		return fmt.Errorf("could not generate key using KMS key(%s): %w", kmsKey, err)
 	}
 
-	return items, nil
-}
-
-func (driver *Driver) GetDeleteTargetWithVersion(name, version, table string) (map[*string]*string, error) {
-	params := &dynamodb.GetItemInput{
-		TableName: aws.String(table),
-		Key: map[string]*dynamodb.AttributeValue{
-			"name":    {S: aws.String(name)},
-			"version": {S: aws.String(version)},
-		},
-	}
+	cipherText := Crypt([]byte(secret), dataKey)
+	hmac := Digest(cipherText, hmacKey)
 
-	resp, err := driver.Ddb.GetItem(params)
+	err = driver.Backend.PutItem(ctx, name, version, wrappedKey, cipherText, hmac, table)
 	if err != nil {
-		return nil, fmt.Errorf("can't find deletion target: %w", err)
-	}
-
-	if resp.Item == nil {
-		versionNum := Atoi(version)
-		return nil, fmt.Errorf(`%w: {"name": %q, "version": %d}`, ErrItemNotFound, name, versionNum)
-	}
-
-	items := map[*string]*string{}
-	items[resp.Item["name"].S] = resp.Item["version"].S
-
-	return items, nil
-}
-
-func (driver *Driver) DeleteItem(name, version, table string) error {
-	svc := driver.Ddb
-
-	params := &dynamodb.DeleteItemInput{
-		TableName: aws.String(table),
-		Key: map[string]*dynamodb.AttributeValue{
-			"name":    {S: aws.String(name)},
-			"version": {S: aws.String(version)},
-		},
-	}
+		if errors.Is(err, ErrItemExists) {
+			latestVersion, err := driver.Backend.GetHighestVersion(ctx, name, table)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
 
-	if _, err := svc.DeleteItem(params); err != nil {
-		return fmt.Errorf("can't delete secret %q (%v): %w", name, version, err)
+			return fmt.Errorf("%w (name: %q, version: %d)", ErrVersionExists, name, latestVersion)
+		}
+		return err
 	}
 
 	return nil
 }
 
-func (driver *Driver) DeleteSecrets(name, version, table string) error {
-	var items map[*string]*string
-	var err error
-
-	if version == "" {
-		items, err = driver.GetDeleteTargetWithoutVersion(name, table)
-	} else {
-		items, err = driver.GetDeleteTargetWithVersion(name, version, table)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	for name, version := range items {
-		err := driver.DeleteItem(*name, *version, table)
+// PutSecretAutoVersion stores secret under the version after
+// GetHighestVersion, retrying with the next version if it loses a race with
+// a concurrent writer, for at most maxAutoVersionAttempts attempts in all,
+// so callers don't have to pass -v themselves.
+func (driver *Driver) PutSecretAutoVersion(ctx context.Context, name, secret, kmsKey, table string, ctxMap map[string]string) error {
+	for attempt := 0; attempt < maxAutoVersionAttempts; attempt++ {
+		highestVersion, err := driver.Backend.GetHighestVersion(ctx, name, table)
 		if err != nil {
+			//nolint:wrapcheck
 			return err
 		}
 
-		versionNum := Atoi(*version)
-		fmt.Fprintf(os.Stderr, "Deleting %s -- version %d\n", *name, versionNum)
-	}
-
-	return nil
-}
-
-func (driver *Driver) PutSecret(name, secret, version, kmsKey, table string, context map[string]string) error {
-	dataKey, hmacKey, wrappedKey, err := KmsGenerateDataKey(driver.Kms, kmsKey, context)
-	if err != nil {
-		return fmt.Errorf("could not generate key using KMS key(%s): %w", kmsKey, err)
-	}
+		version := strconv.Itoa(highestVersion + 1)
 
-	cipherText := Crypt([]byte(secret), dataKey)
-	hmac := Digest(cipherText, hmacKey)
-
-	err = driver.PutItem(name, version, wrappedKey, cipherText, hmac, table)
-
-	if err != nil {
-		if strings.Contains(err.Error(), "ConditionalCheckFailedException") {
-			latestVersion, err := driver.GetHighestVersion(name, table)
-			if err != nil {
-				//nolint:wrapcheck
-				return err
-			}
+		err = driver.PutSecret(ctx, name, secret, version, kmsKey, table, ctxMap)
+		if err == nil {
+			return nil
+		}
 
-			return fmt.Errorf("%w (name: %q, version: %d)", ErrVersionExists, name, latestVersion)
+		if !errors.Is(err, ErrVersionExists) {
+			return err
 		}
-		return err
 	}
 
-	return nil
+	return fmt.Errorf("%w: %s (after %d attempts)", ErrVersionExists, name, maxAutoVersionAttempts)
 }
 
-func (driver *Driver) GetSecret(name, version, table string, context map[string]string) (string, error) {
-	var material map[string]*dynamodb.AttributeValue
+func (driver *Driver) GetSecret(ctx context.Context, name, version, table string, ctxMap map[string]string) (string, error) {
+	var material *Material
 	var err error
 
 	if version == "" {
-		material, err = driver.GetMaterialWithoutVersion(name, table)
+		material, err = driver.Backend.GetMaterialWithoutVersion(ctx, name, table)
 	} else {
-		material, err = driver.GetMaterialWithVersion(name, version, table)
+		material, err = driver.Backend.GetMaterialWithVersion(ctx, name, version, table)
 	}
 
 	if err != nil {
 		return "", fmt.Errorf("can't fetch secret: %w", err)
 	}
 
-	value, err := driver.DecryptMaterial(name, material, context)
+	value, err := driver.DecryptMaterial(ctx, name, material, ctxMap)
 	if err != nil {
 		return "", fmt.Errorf("can't decrypt secret: %w", err)
 	}
@@ -320,25 +169,54 @@ func (driver *Driver) GetSecret(name, version, table string, context map[string]
 	return value, nil
 }
 
-func (driver *Driver) ListSecrets(table string) (map[*string]*string, error) {
-	svc := driver.Ddb
+func (driver *Driver) DeleteSecrets(ctx context.Context, name, version, table string) error {
+	//nolint:wrapcheck
+	return driver.Backend.DeleteSecrets(ctx, name, version, table)
+}
 
-	params := &dynamodb.ScanInput{
-		TableName:                aws.String(table),
-		ProjectionExpression:     aws.String("#name,version"),
-		ExpressionAttributeNames: map[string]*string{"#name": aws.String("name")},
+func (driver *Driver) ListSecrets(ctx context.Context, table string) (map[string]string, error) {
+	//nolint:wrapcheck
+	return driver.Backend.ListSecrets(ctx, table)
+}
+
+func (driver *Driver) GetHighestVersion(ctx context.Context, name, table string) (int, error) {
+	//nolint:wrapcheck
+	return driver.Backend.GetHighestVersion(ctx, name, table)
+}
+
+// RewrapSecret decrypts name's version (under oldCtx) and writes the result
+// back as a new version encrypted under newKmsKey/newCtx, so a KMS CMK or
+// encryption context can be rotated without ever exposing the plaintext
+// outside this process. The write is conditional on version still matching
+// what was just decrypted, so a concurrent rotate or delete of it fails the
+// call instead of racing it.
+func (driver *Driver) RewrapSecret(ctx context.Context, name, version, newKmsKey string, newCtx map[string]string, table string, oldCtx map[string]string) error {
+	material, err := driver.Backend.GetMaterialWithVersion(ctx, name, version, table)
+	if err != nil {
+		return fmt.Errorf("can't fetch secret: %w", err)
+	}
+
+	secret, err := driver.DecryptMaterial(ctx, name, material, oldCtx)
+	if err != nil {
+		return fmt.Errorf("can't decrypt secret: %w", err)
 	}
 
-	resp, err := svc.Scan(params)
+	dataKey, hmacKey, wrappedKey, err := KmsGenerateDataKey(ctx, driver.Kms, newKmsKey, newCtx)
 	if err != nil {
-		return nil, fmt.Errorf("can't list secrets: %w", err)
+		return fmt.Errorf("could not generate key using KMS key(%s): %w", newKmsKey, err)
 	}
 
-	items := map[*string]*string{}
+	cipherText := Crypt([]byte(secret), dataKey)
+	hmac := Digest(cipherText, hmacKey)
 
-	for _, i := range resp.Items {
-		items[i["name"].S] = i["version"].S
+	highestVersion, err := driver.Backend.GetHighestVersion(ctx, name, table)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
 	}
 
-	return items, nil
+	newVersion := strconv.Itoa(highestVersion + 1)
+
+	//nolint:wrapcheck
+	return driver.Backend.RewrapItem(ctx, name, version, newVersion, material, wrappedKey, cipherText, hmac, table)
 }
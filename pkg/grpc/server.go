@@ -0,0 +1,92 @@
+// Package grpc exposes internal.Driver operations as a gRPC service so
+// that container sidecars, CI runners and other apps can fetch and store
+// secrets without shelling out to the gcredstash binary or embedding AWS
+// credentials of their own.
+package grpc
+
+import (
+	"context"
+
+	"github.com/kgaughan/gcredstash/internal"
+	"github.com/ryanuber/go-glob"
+)
+
+// Server implements CredentialsServer on top of a *internal.Driver.
+type Server struct {
+	UnimplementedCredentialsServer
+
+	Driver *internal.Driver
+}
+
+// NewServer returns a Server that serves the Credentials RPCs using driver.
+func NewServer(driver *internal.Driver) *Server {
+	return &Server{Driver: driver}
+}
+
+func (s *Server) GetSecret(ctx context.Context, req *GetSecretRequest) (*GetSecretResponse, error) {
+	value, err := s.Driver.GetSecret(ctx, req.GetName(), req.GetVersion(), req.GetTable(), req.GetContext())
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return &GetSecretResponse{Value: value}, nil
+}
+
+// GetSecrets matches req.Name as a glob against every name in the table,
+// mirroring GetCommand.getCredentials: names that fail to decrypt are
+// silently skipped rather than failing the whole call.
+func (s *Server) GetSecrets(ctx context.Context, req *GetSecretsRequest) (*GetSecretsResponse, error) {
+	items, err := s.Driver.ListSecrets(ctx, req.GetTable())
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	creds := map[string]string{}
+
+	for name := range items {
+		if !glob.Glob(req.GetName(), name) {
+			continue
+		}
+
+		value, err := s.Driver.GetSecret(ctx, name, req.GetVersion(), req.GetTable(), req.GetContext())
+		if err != nil {
+			continue
+		}
+
+		creds[name] = value
+	}
+
+	return &GetSecretsResponse{Credentials: creds}, nil
+}
+
+func (s *Server) PutSecret(ctx context.Context, req *PutSecretRequest) (*PutSecretResponse, error) {
+	err := s.Driver.PutSecret(ctx, req.GetName(), req.GetSecret(), req.GetVersion(), req.GetKmsKey(), req.GetTable(), req.GetContext())
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return &PutSecretResponse{}, nil
+}
+
+func (s *Server) DeleteSecrets(ctx context.Context, req *DeleteSecretsRequest) (*DeleteSecretsResponse, error) {
+	err := s.Driver.DeleteSecrets(ctx, req.GetName(), req.GetVersion(), req.GetTable())
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return &DeleteSecretsResponse{}, nil
+}
+
+func (s *Server) ListSecrets(ctx context.Context, req *ListSecretsRequest) (*ListSecretsResponse, error) {
+	items, err := s.Driver.ListSecrets(ctx, req.GetTable())
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return &ListSecretsResponse{Versions: items}, nil
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// DynamoDBAPI is the subset of *dynamodb.Client (or a DAX client with the
+// same shape) that InstrumentedDynamoDB wraps. It mirrors
+// internal.DynamoDBAPI structurally so callers there can pass their client
+// in and assign the wrapped result straight back, without this package
+// importing internal.
+type DynamoDBAPI interface {
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// InstrumentedDynamoDB wraps a DynamoDBAPI, recording gcredstash_ddb_requests_total
+// and gcredstash_operation_duration_seconds for every call.
+type InstrumentedDynamoDB struct {
+	DynamoDBAPI
+
+	Registry *Registry
+}
+
+// NewDynamoDB wraps api so its calls are recorded against registry.
+func NewDynamoDB(api DynamoDBAPI, registry *Registry) *InstrumentedDynamoDB {
+	return &InstrumentedDynamoDB{DynamoDBAPI: api, Registry: registry}
+}
+
+func (ddb *InstrumentedDynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.Query(ctx, params, optFns...)
+	ddb.record("Query", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.GetItem(ctx, params, optFns...)
+	ddb.record("GetItem", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.PutItem(ctx, params, optFns...)
+	ddb.record("PutItem", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.DeleteItem(ctx, params, optFns...)
+	ddb.record("DeleteItem", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.Scan(ctx, params, optFns...)
+	ddb.record("Scan", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	start := time.Now()
+	resp, err := ddb.DynamoDBAPI.TransactWriteItems(ctx, params, optFns...)
+	ddb.record("TransactWriteItems", start, err)
+
+	return resp, err
+}
+
+func (ddb *InstrumentedDynamoDB) record(op string, start time.Time, err error) {
+	ddb.Registry.DdbRequestsTotal.WithLabelValues(op, result(err)).Inc()
+	ddb.Registry.OperationDuration.WithLabelValues("ddb."+op).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// KMSAPI mirrors internal.KMSAPI structurally, letting InstrumentedKMS wrap
+// a Driver's KMS client without this package importing internal.
+type KMSAPI interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+}
+
+// InstrumentedKMS wraps a KMSAPI, recording gcredstash_kms_requests_total
+// and gcredstash_operation_duration_seconds for every call.
+type InstrumentedKMS struct {
+	KMSAPI
+
+	Registry *Registry
+}
+
+// NewKMS wraps api so its calls are recorded against registry.
+func NewKMS(api KMSAPI, registry *Registry) *InstrumentedKMS {
+	return &InstrumentedKMS{KMSAPI: api, Registry: registry}
+}
+
+func (k *InstrumentedKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	start := time.Now()
+	resp, err := k.KMSAPI.Decrypt(ctx, params, optFns...)
+	k.record("Decrypt", start, err)
+
+	return resp, err
+}
+
+func (k *InstrumentedKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	start := time.Now()
+	resp, err := k.KMSAPI.GenerateDataKey(ctx, params, optFns...)
+	k.record("GenerateDataKey", start, err)
+
+	return resp, err
+}
+
+func (k *InstrumentedKMS) record(op string, start time.Time, err error) {
+	k.Registry.KmsRequestsTotal.WithLabelValues(op, result(err)).Inc()
+	k.Registry.OperationDuration.WithLabelValues("kms."+op).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,66 @@
+// Package metrics instruments internal.Driver's DynamoDB and KMS clients
+// with Prometheus counters and histograms, so a long-lived `gcredstash
+// serve` process can expose them over HTTP alongside its gRPC service.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds a process-local set of gcredstash metrics, each registered
+// against its own prometheus.Registry rather than the global default one so
+// a gRPC server and a metrics server can share a process without either
+// pulling in the other's collectors.
+type Registry struct {
+	registry *prometheus.Registry
+
+	DdbRequestsTotal  *prometheus.CounterVec
+	KmsRequestsTotal  *prometheus.CounterVec
+	OperationDuration *prometheus.HistogramVec
+	DaxReadsTotal     prometheus.Counter
+}
+
+// NewRegistry creates and registers the gcredstash metric collectors.
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+
+	metrics := &Registry{
+		registry: reg,
+		DdbRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gcredstash_ddb_requests_total",
+			Help: "Total DynamoDB requests issued by the Driver, by operation and result.",
+		}, []string{"op", "result"}),
+		KmsRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gcredstash_kms_requests_total",
+			Help: "Total KMS requests issued by the Driver, by operation and result.",
+		}, []string{"op", "result"}),
+		OperationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "gcredstash_operation_duration_seconds",
+			Help: "Duration of each DynamoDB and KMS call made by the Driver, by client operation (e.g. ddb.Query, kms.Decrypt). Driver methods themselves are not timed.",
+		}, []string{"op"}),
+		DaxReadsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gcredstash_dax_reads_total",
+			Help: "Total GetSecret reads issued through a DAX-backed client rather than DynamoDB directly. The DAX client gives no hit/miss signal, so this counts every such read, not just cache hits.",
+		}),
+	}
+
+	reg.MustRegister(metrics.DdbRequestsTotal, metrics.KmsRequestsTotal, metrics.OperationDuration, metrics.DaxReadsTotal)
+
+	return metrics
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func (metrics *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{})
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "success"
+}
@@ -1,9 +1,12 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/kgaughan/gcredstash/src/gcredstash"
 	"github.com/ryanuber/go-glob"
@@ -47,14 +50,14 @@ func (c *GetCommand) parseArgs(args []string) (string, string, map[string]string
 	}
 
 	credential := newArgs[0]
-	context, err := gcredstash.ParseContext(newArgs[1:])
+	ctxMap, err := gcredstash.ParseContext(newArgs[1:])
 
 	//nolint:wrapcheck
-	return credential, version, context, noNL, noErr, errOut, err
+	return credential, version, ctxMap, noNL, noErr, errOut, err
 }
 
-func (c *GetCommand) getCredential(credential, version string, context map[string]string) (string, error) {
-	value, err := c.Driver.GetSecret(credential, version, c.Table, context)
+func (c *GetCommand) getCredential(ctx context.Context, credential, version string, ctxMap map[string]string) (string, error) {
+	value, err := c.Driver.GetSecret(ctx, credential, version, c.Table, ctxMap)
 	if err != nil {
 		//nolint:wrapcheck
 		return "", err
@@ -63,26 +66,21 @@ func (c *GetCommand) getCredential(credential, version string, context map[strin
 	return value, nil
 }
 
-func (c *GetCommand) getCredentials(credential, version string, context map[string]string) (string, error) {
-	names := map[string]bool{}
-	items, err := c.Driver.ListSecrets(c.Table)
+func (c *GetCommand) getCredentials(ctx context.Context, credential, version string, ctxMap map[string]string) (string, error) {
+	items, err := c.Driver.ListSecrets(ctx, c.Table)
 	if err != nil {
 		//nolint:wrapcheck
 		return "", err
 	}
 
-	for name := range items {
-		names[*name] = true
-	}
-
 	creds := map[string]string{}
 
-	for name := range names {
+	for name := range items {
 		if !glob.Glob(credential, name) {
 			continue
 		}
 
-		value, err := c.Driver.GetSecret(name, version, c.Table, context)
+		value, err := c.Driver.GetSecret(ctx, name, version, c.Table, ctxMap)
 		if err != nil {
 			continue
 		}
@@ -109,14 +107,14 @@ func (c *GetCommand) write(filename, message string) {
 	fp.WriteString(message)
 }
 
-func (c *GetCommand) RunImpl(args []string) (string, error) {
-	credential, version, context, noNL, noErr, errOut, err := c.parseArgs(args)
+func (c *GetCommand) RunImpl(ctx context.Context, args []string) (string, error) {
+	credential, version, ctxMap, noNL, noErr, errOut, err := c.parseArgs(args)
 	if err != nil {
 		return "", err
 	}
 
 	if strings.Contains(credential, "*") {
-		value, err := c.getCredentials(credential, version, context)
+		value, err := c.getCredentials(ctx, credential, version, ctxMap)
 
 		if err != nil && errOut != "" {
 			c.write(errOut, fmt.Sprintf("error: gcredstash get %v: %s\n", args, err.Error()))
@@ -125,7 +123,7 @@ func (c *GetCommand) RunImpl(args []string) (string, error) {
 		return value, err
 	}
 
-	value, err := c.getCredential(credential, version, context)
+	value, err := c.getCredential(ctx, credential, version, ctxMap)
 	if err != nil {
 		if errOut != "" {
 			c.write(errOut, fmt.Sprintf("error: gcredstash get %v: %s\n", args, err.Error()))
@@ -146,7 +144,10 @@ func (c *GetCommand) RunImpl(args []string) (string, error) {
 }
 
 func (c *GetCommand) Run(args []string) int {
-	out, err := c.RunImpl(args)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	out, err := c.RunImpl(ctx, args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
 		return 1
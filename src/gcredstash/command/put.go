@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kgaughan/gcredstash/src/gcredstash"
+)
+
+type PutCommand struct {
+	Meta
+}
+
+var ErrVersionAndAutoVersion = errors.New("can't specify both -v and -a")
+
+func (c *PutCommand) parseArgs(args []string) (string, string, string, bool, string, map[string]string, error) {
+	argsWithoutA, autoVersion := gcredstash.HasOption(args, "-a")
+
+	argsWithoutKey, kmsKey, err := gcredstash.ParseOptionWithValue(argsWithoutA, "-key")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", false, "", nil, err
+	}
+
+	if kmsKey == "" {
+		kmsKey = os.Getenv("GCREDSTASH_KMS_KEY")
+	}
+
+	if kmsKey == "" {
+		kmsKey = "alias/credstash"
+	}
+
+	newArgs, version, err := gcredstash.ParseVersion(argsWithoutKey)
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", false, "", nil, err
+	}
+
+	if autoVersion && version != "" {
+		return "", "", "", false, "", nil, ErrVersionAndAutoVersion
+	}
+
+	if len(newArgs) < 2 {
+		return "", "", "", false, "", nil, ErrTooFewArgs
+	}
+
+	name, secret := newArgs[0], newArgs[1]
+
+	ctxMap, err := gcredstash.ParseContext(newArgs[2:])
+
+	//nolint:wrapcheck
+	return name, secret, version, autoVersion, kmsKey, ctxMap, err
+}
+
+func (c *PutCommand) RunImpl(ctx context.Context, args []string) (string, error) {
+	name, secret, version, autoVersion, kmsKey, ctxMap, err := c.parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	if autoVersion {
+		if err := c.Driver.PutSecretAutoVersion(ctx, name, secret, kmsKey, c.Table, ctxMap); err != nil {
+			//nolint:wrapcheck
+			return "", err
+		}
+
+		return fmt.Sprintf("%s has been stored\n", name), nil
+	}
+
+	if version == "" {
+		version = "1"
+	}
+
+	if err := c.Driver.PutSecret(ctx, name, secret, version, kmsKey, c.Table, ctxMap); err != nil {
+		//nolint:wrapcheck
+		return "", err
+	}
+
+	return fmt.Sprintf("%s has been stored\n", name), nil
+}
+
+func (c *PutCommand) Run(args []string) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	out, err := c.RunImpl(ctx, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		return 1
+	}
+
+	fmt.Print(out)
+
+	return 0
+}
+
+func (c *PutCommand) Synopsis() string {
+	return "Store a credential in the store"
+}
+
+func (c *PutCommand) Help() string {
+	helpText := `
+usage: gcredstash put [-v VERSION | -a] [-key KMSKEY] name secret [context [context ...]]
+
+  VERSION defaults to 1. -a stores the next version after the highest one
+  already in the store, retrying if it loses a race with a concurrent put;
+  it can't be combined with -v. KMSKEY defaults to $GCREDSTASH_KMS_KEY, or
+  alias/credstash.
+`
+	return strings.TrimSpace(helpText)
+}
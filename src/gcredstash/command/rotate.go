@@ -0,0 +1,116 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/kgaughan/gcredstash/src/gcredstash"
+)
+
+type RotateCommand struct {
+	Meta
+}
+
+var (
+	ErrNewKeyRequired    = errors.New("rotate requires -new-key KMSKEY")
+	ErrNotHighestVersion = errors.New("rotate only re-encrypts the highest version of a credential - rewrapping an older one would promote its stale value above newer versions")
+)
+
+func (c *RotateCommand) parseArgs(args []string) (string, string, string, map[string]string, error) {
+	argsWithoutKey, newKmsKey, err := gcredstash.ParseOptionWithValue(args, "-new-key")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", nil, err
+	}
+
+	if newKmsKey == "" {
+		return "", "", "", nil, ErrNewKeyRequired
+	}
+
+	newArgs, version, err := gcredstash.ParseVersion(argsWithoutKey)
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", nil, err
+	}
+
+	if len(newArgs) < 1 {
+		return "", "", "", nil, ErrTooFewArgs
+	}
+
+	name := newArgs[0]
+
+	ctxMap, err := gcredstash.ParseContext(newArgs[1:])
+
+	//nolint:wrapcheck
+	return name, version, newKmsKey, ctxMap, err
+}
+
+func (c *RotateCommand) RunImpl(ctx context.Context, args []string) (string, error) {
+	name, version, newKmsKey, ctxMap, err := c.parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	highestVersion, err := c.Driver.GetHighestVersion(ctx, name, c.Table)
+	if err != nil {
+		//nolint:wrapcheck
+		return "", err
+	}
+
+	if version == "" {
+		version = strconv.Itoa(highestVersion)
+	} else if version != strconv.Itoa(highestVersion) {
+		return "", fmt.Errorf("%w (got %s, highest is %d)", ErrNotHighestVersion, version, highestVersion)
+	}
+
+	// Rotation keeps the encryption context as-is; only the KMS key changes.
+	if err := c.Driver.RewrapSecret(ctx, name, version, newKmsKey, ctxMap, c.Table, ctxMap); err != nil {
+		//nolint:wrapcheck
+		return "", err
+	}
+
+	return fmt.Sprintf("%s (version %s) has been rewrapped under %s\n", name, version, newKmsKey), nil
+}
+
+func (c *RotateCommand) Run(args []string) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	out, err := c.RunImpl(ctx, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		return 1
+	}
+
+	fmt.Print(out)
+
+	return 0
+}
+
+func (c *RotateCommand) Synopsis() string {
+	return "Re-encrypt a stored credential under a new KMS key"
+}
+
+func (c *RotateCommand) Help() string {
+	helpText := `
+usage: gcredstash rotate -new-key KMSKEY [-v VERSION] name [context [context ...]]
+
+  Fetches name's highest version, decrypts it, and writes the result back
+  as a new version encrypted under KMSKEY -- without ever writing the
+  plaintext to disk or the shell. The write is conditional on the version
+  being rewrapped still being present, so a concurrent delete or rotate of
+  it fails the command instead of racing it.
+
+  VERSION, if given, must equal the current highest version; it only lets
+  a caller assert which version it expects to rotate, guarding against a
+  race with a concurrent put. Rotating an older version isn't supported --
+  doing so would republish its stale value as the new highest version.
+`
+	return strings.TrimSpace(helpText)
+}
@@ -0,0 +1,213 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/kgaughan/gcredstash/pkg/grpc"
+	"github.com/kgaughan/gcredstash/src/gcredstash"
+)
+
+type ServeCommand struct {
+	Meta
+}
+
+func (c *ServeCommand) parseArgs(args []string) (string, string, string, string, error) {
+	argsWithoutListen, listen, err := gcredstash.ParseOptionWithValue(args, "-listen")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", "", err
+	}
+
+	if listen == "" {
+		listen = os.Getenv("GCREDSTASH_LISTEN")
+	}
+
+	if listen == "" {
+		listen = "unix:///var/run/gcredstash.sock"
+	}
+
+	argsWithoutCert, certFile, err := gcredstash.ParseOptionWithValue(argsWithoutListen, "-tls-cert")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", "", err
+	}
+
+	argsWithoutKey, keyFile, err := gcredstash.ParseOptionWithValue(argsWithoutCert, "-tls-key")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", "", err
+	}
+
+	_, metricsAddr, err := gcredstash.ParseOptionWithValue(argsWithoutKey, "-metrics-addr")
+	if err != nil {
+		//nolint:wrapcheck
+		return "", "", "", "", err
+	}
+
+	if metricsAddr == "" {
+		metricsAddr = os.Getenv("GCREDSTASH_METRICS_ADDR")
+	}
+
+	return listen, certFile, keyFile, metricsAddr, nil
+}
+
+func (c *ServeCommand) listener(listen string) (net.Listener, error) {
+	if addr, ok := strings.CutPrefix(listen, "unix://"); ok {
+		if err := removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+
+		//nolint:wrapcheck
+		return net.Listen("unix", addr)
+	}
+
+	addr, _ := strings.CutPrefix(listen, "tcp://")
+
+	//nolint:wrapcheck
+	return net.Listen("tcp", addr)
+}
+
+// removeStaleSocket removes addr if it's a unix socket left behind by a
+// process that's no longer listening on it -- e.g. after a crash or
+// SIGKILL -- so the server can rebind without failing with "address
+// already in use". It leaves addr alone if something is actually
+// listening on it, or if it doesn't exist at all.
+func removeStaleSocket(addr string) error {
+	conn, err := net.Dial("unix", addr)
+	if err == nil {
+		conn.Close()
+		return nil
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("can't remove stale socket %s: %w", addr, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *ServeCommand) serverOptions(certFile, keyFile string) ([]grpclib.ServerOption, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't load TLS certificate: %w", err)
+	}
+
+	creds := credentials.NewServerTLSFromCert(&cert)
+
+	return []grpclib.ServerOption{grpclib.Creds(creds)}, nil
+}
+
+// metricsServer starts an HTTP server exposing c.Driver.Metrics on addr, and
+// returns a function that gracefully shuts it down. It logs failures to
+// os.Stderr rather than failing RunImpl, since a broken metrics endpoint
+// shouldn't take down the gRPC server.
+func (c *ServeCommand) metricsServer(addr string) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c.Driver.Metrics.Handler())
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		fmt.Fprintf(os.Stderr, "Serving metrics on %s\n", addr)
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "error: metrics server stopped: %s\n", err.Error())
+		}
+	}()
+
+	return func() {
+		//nolint:errcheck
+		srv.Shutdown(context.Background())
+	}
+}
+
+func (c *ServeCommand) RunImpl(ctx context.Context, args []string) (string, error) {
+	listen, certFile, keyFile, metricsAddr, err := c.parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	lis, err := c.listener(listen)
+	if err != nil {
+		return "", fmt.Errorf("can't listen on %s: %w", listen, err)
+	}
+	defer lis.Close()
+
+	opts, err := c.serverOptions(certFile, keyFile)
+	if err != nil {
+		return "", err
+	}
+
+	srv := grpclib.NewServer(opts...)
+	grpc.RegisterCredentialsServer(srv, grpc.NewServer(c.Driver))
+
+	if metricsAddr != "" {
+		stopMetrics := c.metricsServer(metricsAddr)
+		defer stopMetrics()
+	}
+
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", listen)
+
+	if err := srv.Serve(lis); err != nil {
+		return "", fmt.Errorf("gRPC server stopped: %w", err)
+	}
+
+	return "", nil
+}
+
+func (c *ServeCommand) Run(args []string) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	_, err := c.RunImpl(ctx, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (c *ServeCommand) Synopsis() string {
+	return "Start a gRPC server exposing credential store operations"
+}
+
+func (c *ServeCommand) Help() string {
+	helpText := `
+usage: gcredstash serve [-listen ADDR] [-tls-cert FILE -tls-key FILE] [-metrics-addr ADDR]
+
+  ADDR defaults to $GCREDSTASH_LISTEN, or unix:///var/run/gcredstash.sock.
+  A tcp:// or unix:// scheme selects the listener; plain host:port is
+  treated as tcp. If -tls-cert/-tls-key are given, the server terminates
+  TLS itself; otherwise it serves plaintext, which is only appropriate
+  over a unix socket or inside a trusted network.
+
+  -metrics-addr (or $GCREDSTASH_METRICS_ADDR) starts a second, plaintext
+  HTTP listener on ADDR serving Prometheus metrics at /metrics. It's left
+  unset - and the listener not started - by default.
+`
+	return strings.TrimSpace(helpText)
+}